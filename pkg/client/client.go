@@ -1,15 +1,23 @@
 package client
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"net/url"
 	"path"
+	"time"
 
 	"github.com/bakito/adguardhome-sync/pkg/log"
 	"github.com/bakito/adguardhome-sync/pkg/types"
 	"github.com/go-resty/resty/v2"
 	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultRetryWaitMin = 1 * time.Second
+	defaultRetryWaitMax = 30 * time.Second
 )
 
 var (
@@ -39,98 +47,160 @@ func New(config types.AdGuardInstance) (Client, error) {
 		cl = cl.SetBasicAuth(config.Username, config.Password)
 	}
 
+	retryWaitMin := config.RetryWaitMin
+	if retryWaitMin == 0 {
+		retryWaitMin = defaultRetryWaitMin
+	}
+	retryWaitMax := config.RetryWaitMax
+	if retryWaitMax == 0 {
+		retryWaitMax = defaultRetryWaitMax
+	}
+
+	if config.MaxRetries > 0 {
+		cl.SetRetryCount(config.MaxRetries).
+			SetRetryWaitTime(retryWaitMin).
+			SetRetryMaxWaitTime(retryWaitMax).
+			AddRetryCondition(func(r *resty.Response, err error) bool {
+				return err != nil || r.StatusCode() >= 500
+			})
+	}
+
+	var limiter *rate.Limiter
+	if config.RequestsPerSecond > 0 {
+		limiter = rate.NewLimiter(rate.Limit(config.RequestsPerSecond), 1)
+	}
+
 	return &client{
-		host:   u.Host,
-		client: cl,
-		log:    l.With("host", u.Host),
+		host:    u.Host,
+		client:  cl,
+		limiter: limiter,
+		log:     l.With("host", u.Host),
 	}, nil
 }
 
 type Client interface {
 	Host() string
 
-	Status() (*types.Status, error)
-	RewriteList() (*types.RewriteEntries, error)
-	AddRewriteEntries(e ...types.RewriteEntry) error
-	DeleteRewriteEntries(e ...types.RewriteEntry) error
-
-	Filtering() (*types.FilteringStatus, error)
-	ToggleFiltering(enabled bool, interval int) error
-	AddFilters(whitelist bool, e ...types.Filter) error
-	DeleteFilters(whitelist bool, e ...types.Filter) error
-	RefreshFilters(whitelist bool) error
-	SetCustomRules(rules types.UserRules) error
-
-	ToggleSaveBrowsing(enable bool) error
-	ToggleParental(enable bool) error
-	ToggleSafeSearch(enable bool) error
-
-	Services() (*types.Services, error)
-	SetServices(services types.Services) error
-
-	Clients() (*types.Clients, error)
-	AddClients(client ...types.Client) error
-	UpdateClients(client ...types.Client) error
-	DeleteClients(client ...types.Client) error
+	Status(ctx context.Context) (*types.Status, error)
+	RewriteList(ctx context.Context) (*types.RewriteEntries, error)
+	AddRewriteEntries(ctx context.Context, e ...types.RewriteEntry) error
+	DeleteRewriteEntries(ctx context.Context, e ...types.RewriteEntry) error
+
+	Filtering(ctx context.Context) (*types.FilteringStatus, error)
+	ToggleFiltering(ctx context.Context, enabled bool, interval int) error
+	AddFilters(ctx context.Context, whitelist bool, e ...types.Filter) error
+	DeleteFilters(ctx context.Context, whitelist bool, e ...types.Filter) error
+	RefreshFilters(ctx context.Context, whitelist bool) error
+	SetCustomRules(ctx context.Context, rules types.UserRules) error
+
+	ToggleSaveBrowsing(ctx context.Context, enable bool) error
+	ToggleParental(ctx context.Context, enable bool) error
+	ToggleSafeSearch(ctx context.Context, enable bool) error
+
+	Services(ctx context.Context) (*types.Services, error)
+	SetServices(ctx context.Context, services types.Services) error
+
+	Clients(ctx context.Context) (*types.Clients, error)
+	AddClients(ctx context.Context, client ...types.Client) error
+	UpdateClients(ctx context.Context, client ...types.Client) error
+	DeleteClients(ctx context.Context, client ...types.Client) error
+
+	QueryLog(ctx context.Context) (*types.QueryLog, error)
+	QueryLogConfig(ctx context.Context) (*types.QueryLogConfig, error)
+	SetQueryLogConfig(ctx context.Context, enabled bool, interval uint64, anonymizeClientIP bool) error
+
+	TLSConfig(ctx context.Context) (*types.TLSConfig, error)
+	SetTLSConfig(ctx context.Context, cfg types.TLSConfig) error
+
+	DHCPStatus(ctx context.Context) (*types.DHCPStatus, error)
+	SetDHCPConfig(ctx context.Context, cfg types.DHCPConfig) error
+	AddDHCPStaticLease(ctx context.Context, l types.DHCPStaticLease) error
+	DeleteDHCPStaticLease(ctx context.Context, l types.DHCPStaticLease) error
 }
 
 type client struct {
-	client *resty.Client
-	log    *zap.SugaredLogger
-	host   string
+	client  *resty.Client
+	log     *zap.SugaredLogger
+	host    string
+	limiter *rate.Limiter
 }
 
 func (cl *client) Host() string {
 	return cl.host
 }
-func (cl *client) Status() (*types.Status, error) {
+
+// request returns a new resty request bound to ctx, blocking until the rate
+// limiter (if configured) admits it or ctx is done.
+func (cl *client) request(ctx context.Context) (*resty.Request, error) {
+	if cl.limiter != nil {
+		if err := cl.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return cl.client.R().SetContext(ctx).EnableTrace(), nil
+}
+
+func (cl *client) Status(ctx context.Context) (*types.Status, error) {
 	status := &types.Status{}
-	_, err := cl.client.R().EnableTrace().SetResult(status).Get("status")
+	req, err := cl.request(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, err = req.SetResult(status).Get("status")
 	return status, err
-
 }
 
-func (cl *client) RewriteList() (*types.RewriteEntries, error) {
+func (cl *client) RewriteList(ctx context.Context) (*types.RewriteEntries, error) {
 	rewrites := &types.RewriteEntries{}
-	_, err := cl.client.R().EnableTrace().SetResult(&rewrites).Get("/rewrite/list")
+	req, err := cl.request(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, err = req.SetResult(&rewrites).Get("/rewrite/list")
 	return rewrites, err
 }
 
-func (cl *client) AddRewriteEntries(entries ...types.RewriteEntry) error {
+func (cl *client) AddRewriteEntries(ctx context.Context, entries ...types.RewriteEntry) error {
 	for _, e := range entries {
 		cl.log.With("domain", e.Domain, "answer", e.Answer).Info("Add rewrite entry")
-		_, err := cl.client.R().EnableTrace().SetBody(&e).Post("/rewrite/add")
+		req, err := cl.request(ctx)
 		if err != nil {
 			return err
 		}
+		if _, err := req.SetBody(&e).Post("/rewrite/add"); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func (cl *client) DeleteRewriteEntries(entries ...types.RewriteEntry) error {
+func (cl *client) DeleteRewriteEntries(ctx context.Context, entries ...types.RewriteEntry) error {
 	for _, e := range entries {
 		cl.log.With("domain", e.Domain, "answer", e.Answer).Info("Delete rewrite entry")
-		_, err := cl.client.R().EnableTrace().SetBody(&e).Post("/rewrite/delete")
+		req, err := cl.request(ctx)
 		if err != nil {
 			return err
 		}
+		if _, err := req.SetBody(&e).Post("/rewrite/delete"); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func (cl *client) ToggleSaveBrowsing(enable bool) error {
-	return cl.toggle("safebrowsing", enable)
+func (cl *client) ToggleSaveBrowsing(ctx context.Context, enable bool) error {
+	return cl.toggle(ctx, "safebrowsing", enable)
 }
 
-func (cl *client) ToggleParental(enable bool) error {
-	return cl.toggle("parental", enable)
+func (cl *client) ToggleParental(ctx context.Context, enable bool) error {
+	return cl.toggle(ctx, "parental", enable)
 }
 
-func (cl *client) ToggleSafeSearch(enable bool) error {
-	return cl.toggle("safesearch", enable)
+func (cl *client) ToggleSafeSearch(ctx context.Context, enable bool) error {
+	return cl.toggle(ctx, "safesearch", enable)
 }
 
-func (cl *client) toggle(mode string, enable bool) error {
+func (cl *client) toggle(ctx context.Context, mode string, enable bool) error {
 	cl.log.With("mode", mode, "enable", enable).Info("Toggle")
 	var target string
 	if enable {
@@ -138,105 +208,262 @@ func (cl *client) toggle(mode string, enable bool) error {
 	} else {
 		target = "disable"
 	}
-	_, err := cl.client.R().EnableTrace().Post(fmt.Sprintf("/%s/%s", mode, target))
+	req, err := cl.request(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = req.Post(fmt.Sprintf("/%s/%s", mode, target))
 	return err
 }
 
-func (cl *client) Filtering() (*types.FilteringStatus, error) {
+func (cl *client) Filtering(ctx context.Context) (*types.FilteringStatus, error) {
 	f := &types.FilteringStatus{}
-	_, err := cl.client.R().EnableTrace().SetResult(f).Get("/filtering/status")
+	req, err := cl.request(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, err = req.SetResult(f).Get("/filtering/status")
 	return f, err
 }
 
-func (cl *client) AddFilters(whitelist bool, filters ...types.Filter) error {
+func (cl *client) AddFilters(ctx context.Context, whitelist bool, filters ...types.Filter) error {
 	for _, f := range filters {
 		cl.log.With("url", f.URL, "whitelist", whitelist).Info("Add filter")
 		ff := &types.Filter{Name: f.Name, URL: f.URL, Whitelist: whitelist}
-		_, err := cl.client.R().EnableTrace().SetBody(ff).Post("/filtering/add_url")
+		req, err := cl.request(ctx)
 		if err != nil {
 			return err
 		}
+		if _, err := req.SetBody(ff).Post("/filtering/add_url"); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func (cl *client) DeleteFilters(whitelist bool, filters ...types.Filter) error {
+func (cl *client) DeleteFilters(ctx context.Context, whitelist bool, filters ...types.Filter) error {
 	for _, f := range filters {
 		cl.log.With("url", f.URL, "whitelist", whitelist).Info("Delete filter")
 		ff := &types.Filter{URL: f.URL, Whitelist: whitelist}
-		_, err := cl.client.R().EnableTrace().SetBody(ff).Post("/filtering/remove_url")
+		req, err := cl.request(ctx)
 		if err != nil {
 			return err
 		}
+		if _, err := req.SetBody(ff).Post("/filtering/remove_url"); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func (cl *client) RefreshFilters(whitelist bool) error {
+func (cl *client) RefreshFilters(ctx context.Context, whitelist bool) error {
 	cl.log.With("whitelist", whitelist).Info("Refresh filter")
-	_, err := cl.client.R().EnableTrace().SetBody(&types.RefreshFilter{Whitelist: whitelist}).Post("/filtering/refresh")
+	req, err := cl.request(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = req.SetBody(&types.RefreshFilter{Whitelist: whitelist}).Post("/filtering/refresh")
 	return err
 }
 
-func (cl *client) SetCustomRules(rules types.UserRules) error {
+func (cl *client) SetCustomRules(ctx context.Context, rules types.UserRules) error {
 	cl.log.With("rules", len(rules)).Info("Set user rules")
-	_, err := cl.client.R().EnableTrace().SetBody(rules.String()).Post("/filtering/set_rules")
+	req, err := cl.request(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = req.SetBody(rules.String()).Post("/filtering/set_rules")
 	return err
 }
 
-func (cl *client) ToggleFiltering(enabled bool, interval int) error {
+func (cl *client) ToggleFiltering(ctx context.Context, enabled bool, interval int) error {
 	cl.log.With("enabled", enabled, "interval", interval).Info("Toggle filtering")
-	_, err := cl.client.R().EnableTrace().SetBody(&types.FilteringConfig{Enabled: enabled, Interval: interval}).Post("/filtering/config")
+	req, err := cl.request(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = req.SetBody(&types.FilteringConfig{Enabled: enabled, Interval: interval}).Post("/filtering/config")
 	return err
 }
 
-func (cl *client) Services() (*types.Services, error) {
+func (cl *client) Services(ctx context.Context) (*types.Services, error) {
 	svcs := &types.Services{}
-	_, err := cl.client.R().EnableTrace().SetResult(svcs).Get("/blocked_services/list")
+	req, err := cl.request(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, err = req.SetResult(svcs).Get("/blocked_services/list")
 	return svcs, err
 }
 
-func (cl *client) SetServices(services types.Services) error {
+func (cl *client) SetServices(ctx context.Context, services types.Services) error {
 	cl.log.With("services", len(services)).Info("Set services")
-	_, err := cl.client.R().EnableTrace().SetBody(&services).Post("/blocked_services/set")
+	req, err := cl.request(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = req.SetBody(&services).Post("/blocked_services/set")
 	return err
 }
 
-func (cl *client) Clients() (*types.Clients, error) {
+func (cl *client) Clients(ctx context.Context) (*types.Clients, error) {
 	clients := &types.Clients{}
-	_, err := cl.client.R().EnableTrace().SetResult(clients).Get("/clients")
+	req, err := cl.request(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, err = req.SetResult(clients).Get("/clients")
 	return clients, err
 }
 
-func (cl *client) AddClients(clients ...types.Client) error {
+func (cl *client) AddClients(ctx context.Context, clients ...types.Client) error {
 	for _, client := range clients {
 		cl.log.With("name", client.Name).Info("Add client")
-		_, err := cl.client.R().EnableTrace().SetBody(&client).Post("/clients/add")
+		req, err := cl.request(ctx)
 		if err != nil {
 			return err
 		}
+		if _, err := req.SetBody(&client).Post("/clients/add"); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func (cl *client) UpdateClients(clients ...types.Client) error {
+func (cl *client) UpdateClients(ctx context.Context, clients ...types.Client) error {
 	for _, client := range clients {
 		cl.log.With("name", client.Name).Info("Update client")
-		_, err := cl.client.R().EnableTrace().SetBody(&types.ClientUpdate{Name: client.Name, Data: client}).Post("/clients/update")
+		req, err := cl.request(ctx)
 		if err != nil {
 			return err
 		}
+		if _, err := req.SetBody(&types.ClientUpdate{Name: client.Name, Data: client}).Post("/clients/update"); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func (cl *client) DeleteClients(clients ...types.Client) error {
+func (cl *client) DeleteClients(ctx context.Context, clients ...types.Client) error {
 	for _, client := range clients {
 		cl.log.With("name", client.Name).Info("Delete client")
-		_, err := cl.client.R().EnableTrace().SetBody(&client).Post("/clients/delete")
+		req, err := cl.request(ctx)
 		if err != nil {
 			return err
 		}
+		if _, err := req.SetBody(&client).Post("/clients/delete"); err != nil {
+			return err
+		}
 	}
 	return nil
 }
+
+func (cl *client) QueryLog(ctx context.Context) (*types.QueryLog, error) {
+	ql := &types.QueryLog{}
+	req, err := cl.request(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, err = req.SetResult(ql).Get("/querylog")
+	return ql, err
+}
+
+func (cl *client) QueryLogConfig(ctx context.Context) (*types.QueryLogConfig, error) {
+	cfg := &types.QueryLogConfig{}
+	req, err := cl.request(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, err = req.SetResult(cfg).Get("/querylog_config")
+	return cfg, err
+}
+
+func (cl *client) SetQueryLogConfig(ctx context.Context, enabled bool, interval uint64, anonymizeClientIP bool) error {
+	cl.log.With("enabled", enabled, "interval", interval, "anonymizeClientIP", anonymizeClientIP).Info("Set query log config")
+	cfg := &types.QueryLogConfig{Enabled: enabled, Interval: interval, AnonymizeClientIP: anonymizeClientIP}
+	req, err := cl.request(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = req.SetBody(cfg).Post("/querylog_config")
+	return err
+}
+
+func (cl *client) TLSConfig(ctx context.Context) (*types.TLSConfig, error) {
+	cfg := &types.TLSConfig{}
+	req, err := cl.request(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, err = req.SetResult(cfg).Get("/tls/status")
+	return cfg, err
+}
+
+func (cl *client) SetTLSConfig(ctx context.Context, cfg types.TLSConfig) error {
+	cl.log.With("serverName", cfg.ServerName, "portHttps", cfg.PortHTTPS).Info("Set TLS config")
+	validateReq, err := cl.request(ctx)
+	if err != nil {
+		return err
+	}
+	validation := &types.TLSConfig{}
+	resp, err := validateReq.SetBody(&cfg).SetResult(validation).Post("/tls/validate")
+	if err != nil {
+		return err
+	}
+	if resp.IsError() {
+		return fmt.Errorf("tls validation request failed: %s", resp.Status())
+	}
+	if validation.WarningValidation != "" {
+		return fmt.Errorf("tls validation failed: %s", validation.WarningValidation)
+	}
+	if cfg.Enabled && (!validation.ValidCert || !validation.ValidKey || !validation.ValidPair || !validation.ValidChain) {
+		return fmt.Errorf("tls validation failed for %s", cfg.ServerName)
+	}
+
+	configureReq, err := cl.request(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = configureReq.SetBody(&cfg).Post("/tls/configure")
+	return err
+}
+
+func (cl *client) DHCPStatus(ctx context.Context) (*types.DHCPStatus, error) {
+	status := &types.DHCPStatus{}
+	req, err := cl.request(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, err = req.SetResult(status).Get("/dhcp/status")
+	return status, err
+}
+
+func (cl *client) SetDHCPConfig(ctx context.Context, cfg types.DHCPConfig) error {
+	cl.log.With("enabled", cfg.Enabled, "interface", cfg.Interface).Info("Set DHCP config")
+	req, err := cl.request(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = req.SetBody(&cfg).Post("/dhcp/set_config")
+	return err
+}
+
+func (cl *client) AddDHCPStaticLease(ctx context.Context, lease types.DHCPStaticLease) error {
+	cl.log.With("mac", lease.MAC, "ip", lease.IP).Info("Add DHCP static lease")
+	req, err := cl.request(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = req.SetBody(&lease).Post("/dhcp/add_static_lease")
+	return err
+}
+
+func (cl *client) DeleteDHCPStaticLease(ctx context.Context, lease types.DHCPStaticLease) error {
+	cl.log.With("mac", lease.MAC, "ip", lease.IP).Info("Delete DHCP static lease")
+	req, err := cl.request(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = req.SetBody(&lease).Post("/dhcp/remove_static_lease")
+	return err
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bakito/adguardhome-sync/pkg/types"
+)
+
+func newTestClient(t *testing.T, server *httptest.Server, cfg types.AdGuardInstance) Client {
+	t.Helper()
+	cfg.URL = server.URL
+	cl, err := New(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error creating client: %v", err)
+	}
+	return cl
+}
+
+func TestRetriesOn5xxUntilSuccess(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) <= 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	cl := newTestClient(t, server, types.AdGuardInstance{
+		MaxRetries:   3,
+		RetryWaitMin: time.Millisecond,
+		RetryWaitMax: 5 * time.Millisecond,
+	})
+
+	if _, err := cl.Status(context.Background()); err != nil {
+		t.Fatalf("expected retries to eventually succeed, got: %v", err)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Fatalf("expected 3 attempts (1 initial + 2 retries), got %d", got)
+	}
+}
+
+func TestRetriesExhausted(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	cl := newTestClient(t, server, types.AdGuardInstance{
+		MaxRetries:   2,
+		RetryWaitMin: time.Millisecond,
+		RetryWaitMax: 5 * time.Millisecond,
+	})
+
+	// a bare 5xx response carries no go-resty transport error, so Status
+	// returns err == nil here; what matters is that every retry was spent.
+	if _, err := cl.Status(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Fatalf("expected 3 attempts (1 initial + 2 retries), got %d", got)
+	}
+}
+
+func TestRateLimiterThrottlesCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	const rps = 50.0
+	cl := newTestClient(t, server, types.AdGuardInstance{RequestsPerSecond: rps})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := cl.Status(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	elapsed := time.Since(start)
+
+	// with burst 1, the 2nd and 3rd calls each wait ~1/rps; allow generous slack.
+	minExpected := time.Duration(2/rps*1000) * time.Millisecond / 2
+	if elapsed < minExpected {
+		t.Fatalf("expected rate limiting to slow calls down to at least %s, took %s", minExpected, elapsed)
+	}
+}
+
+func TestCanceledContextAbortsRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	cl := newTestClient(t, server, types.AdGuardInstance{RequestsPerSecond: 1})
+
+	// exhaust the single burst token so the next call must wait on the limiter.
+	if _, err := cl.Status(context.Background()); err != nil {
+		t.Fatalf("unexpected error priming the limiter: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := cl.Status(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got: %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bakito/adguardhome-sync/pkg/types"
+)
+
+func TestSetTLSConfigValidateTransportError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// close the connection mid-response to force a transport-level error.
+		hj, ok := w.(http.Hijacker)
+		if !ok {
+			t.Fatal("ResponseWriter does not support hijacking")
+		}
+		conn, _, err := hj.Hijack()
+		if err != nil {
+			t.Fatalf("unexpected error hijacking connection: %v", err)
+		}
+		conn.Close()
+	}))
+	defer server.Close()
+
+	cl := newTestClient(t, server, types.AdGuardInstance{})
+	if err := cl.SetTLSConfig(context.Background(), types.TLSConfig{Enabled: true}); err == nil {
+		t.Fatal("expected a transport error from /tls/validate")
+	}
+}
+
+func TestSetTLSConfigWarningValidation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/tls/validate") {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(types.TLSConfig{WarningValidation: "certificate has expired"})
+			return
+		}
+		t.Fatalf("unexpected request to %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	cl := newTestClient(t, server, types.AdGuardInstance{})
+	err := cl.SetTLSConfig(context.Background(), types.TLSConfig{Enabled: true})
+	if err == nil || !strings.Contains(err.Error(), "certificate has expired") {
+		t.Fatalf("expected warning_validation to surface as an error, got: %v", err)
+	}
+}
+
+func TestSetTLSConfigInvalidCertWhenEnabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/tls/validate") {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(types.TLSConfig{ValidCert: false, ValidKey: true, ValidPair: true, ValidChain: true})
+			return
+		}
+		t.Fatalf("unexpected request to %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	cl := newTestClient(t, server, types.AdGuardInstance{})
+	err := cl.SetTLSConfig(context.Background(), types.TLSConfig{Enabled: true, ServerName: "agh.example.com"})
+	if err == nil {
+		t.Fatal("expected an invalid cert to abort before /tls/configure")
+	}
+}
+
+func TestSetTLSConfigHappyPath(t *testing.T) {
+	var configured bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/tls/validate"):
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(types.TLSConfig{ValidCert: true, ValidKey: true, ValidPair: true, ValidChain: true})
+		case strings.HasSuffix(r.URL.Path, "/tls/configure"):
+			configured = true
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Fatalf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	cl := newTestClient(t, server, types.AdGuardInstance{})
+	if err := cl.SetTLSConfig(context.Background(), types.TLSConfig{Enabled: true, ServerName: "agh.example.com"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !configured {
+		t.Fatal("expected /tls/configure to be called after a passing validation")
+	}
+}
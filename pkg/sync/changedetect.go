@@ -0,0 +1,57 @@
+package sync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+
+	"github.com/bakito/adguardhome-sync/pkg/client"
+)
+
+// ChangeDetector hashes a fixed set of master resources to detect drift
+// between sync polls.
+type ChangeDetector struct {
+	lastHash [sha256.Size]byte
+}
+
+// Changed reports whether the master's hashed state differs from the last
+// call to Changed. The first call always reports a change.
+func (d *ChangeDetector) Changed(ctx context.Context, master client.Client) (bool, error) {
+	hash, err := hashMasterState(ctx, master)
+	if err != nil {
+		return false, err
+	}
+
+	changed := hash != d.lastHash
+	d.lastHash = hash
+	return changed, nil
+}
+
+func hashMasterState(ctx context.Context, master client.Client) ([sha256.Size]byte, error) {
+	status, err := master.Status(ctx)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	filtering, err := master.Filtering(ctx)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	clients, err := master.Clients(ctx)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	rewrites, err := master.RewriteList(ctx)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	services, err := master.Services(ctx)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+
+	payload, err := json.Marshal([]interface{}{status, filtering, clients, rewrites, services})
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(payload), nil
+}
@@ -0,0 +1,83 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bakito/adguardhome-sync/pkg/client"
+	"github.com/bakito/adguardhome-sync/pkg/types"
+)
+
+// fakeHashClient serves fixed master resources for hashMasterState, except
+// Services, which is read from the services field so tests can mutate it
+// between calls.
+type fakeHashClient struct {
+	client.Client
+	services types.Services
+	err      error
+}
+
+func (f *fakeHashClient) Status(context.Context) (*types.Status, error) {
+	return &types.Status{}, f.err
+}
+func (f *fakeHashClient) Filtering(context.Context) (*types.FilteringStatus, error) {
+	return &types.FilteringStatus{}, f.err
+}
+func (f *fakeHashClient) Clients(context.Context) (*types.Clients, error) {
+	return &types.Clients{}, f.err
+}
+func (f *fakeHashClient) RewriteList(context.Context) (*types.RewriteEntries, error) {
+	return &types.RewriteEntries{}, f.err
+}
+func (f *fakeHashClient) Services(context.Context) (*types.Services, error) {
+	return &f.services, f.err
+}
+
+func TestChangeDetectorFirstCallAlwaysChanged(t *testing.T) {
+	d := &ChangeDetector{}
+	fake := &fakeHashClient{services: types.Services{"svc-a"}}
+
+	changed, err := d.Changed(context.Background(), fake)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !changed {
+		t.Fatal("expected the first call to report a change")
+	}
+}
+
+func TestChangeDetectorDetectsChange(t *testing.T) {
+	d := &ChangeDetector{}
+	fake := &fakeHashClient{services: types.Services{"svc-a"}}
+
+	if _, err := d.Changed(context.Background(), fake); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	changed, err := d.Changed(context.Background(), fake)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if changed {
+		t.Fatal("expected no change when master state is identical")
+	}
+
+	fake.services = types.Services{"svc-a", "svc-b"}
+	changed, err = d.Changed(context.Background(), fake)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !changed {
+		t.Fatal("expected a change after master services list changed")
+	}
+}
+
+func TestChangeDetectorPropagatesError(t *testing.T) {
+	d := &ChangeDetector{}
+	fake := &fakeHashClient{err: errors.New("boom")}
+
+	if _, err := d.Changed(context.Background(), fake); err == nil {
+		t.Fatal("expected error from master to propagate")
+	}
+}
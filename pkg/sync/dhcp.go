@@ -0,0 +1,83 @@
+// Package sync converges a replica's state with the master, resource by
+// resource.
+package sync
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/bakito/adguardhome-sync/pkg/client"
+	"github.com/bakito/adguardhome-sync/pkg/log"
+	"github.com/bakito/adguardhome-sync/pkg/types"
+)
+
+var l = log.GetLogger("sync")
+
+// DHCP converges the replica's DHCP config and static leases with the
+// master's. A no-op if replicaEnabled is false.
+func DHCP(ctx context.Context, master, replica client.Client, replicaEnabled bool) error {
+	if !replicaEnabled {
+		l.With("host", replica.Host()).Debug("DHCP sync disabled for replica")
+		return nil
+	}
+
+	masterStatus, err := master.DHCPStatus(ctx)
+	if err != nil {
+		return err
+	}
+	replicaStatus, err := replica.DHCPStatus(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !reflect.DeepEqual(masterStatus.V4, replicaStatus.V4) ||
+		!reflect.DeepEqual(masterStatus.V6, replicaStatus.V6) ||
+		masterStatus.Enabled != replicaStatus.Enabled ||
+		masterStatus.Interface != replicaStatus.Interface {
+		cfg := types.DHCPConfig{
+			Enabled:   masterStatus.Enabled,
+			Interface: masterStatus.Interface,
+			V4:        masterStatus.V4,
+			V6:        masterStatus.V6,
+		}
+		if err := replica.SetDHCPConfig(ctx, cfg); err != nil {
+			return err
+		}
+	}
+
+	return syncStaticLeases(ctx, replica, masterStatus.StaticLeases, replicaStatus.StaticLeases)
+}
+
+func syncStaticLeases(ctx context.Context, replica client.Client, master, current []types.DHCPStaticLease) error {
+	want := make(map[string]types.DHCPStaticLease, len(master))
+	for _, lease := range master {
+		want[lease.MAC] = lease
+	}
+	have := make(map[string]types.DHCPStaticLease, len(current))
+	for _, lease := range current {
+		have[lease.MAC] = lease
+	}
+
+	for mac, lease := range want {
+		if existing, ok := have[mac]; !ok || existing != lease {
+			if ok {
+				if err := replica.DeleteDHCPStaticLease(ctx, existing); err != nil {
+					return err
+				}
+			}
+			if err := replica.AddDHCPStaticLease(ctx, lease); err != nil {
+				return err
+			}
+		}
+	}
+
+	for mac, lease := range have {
+		if _, ok := want[mac]; !ok {
+			if err := replica.DeleteDHCPStaticLease(ctx, lease); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bakito/adguardhome-sync/pkg/client"
+	"github.com/bakito/adguardhome-sync/pkg/types"
+)
+
+// fakeDHCPClient records the static leases added/deleted by syncStaticLeases.
+type fakeDHCPClient struct {
+	client.Client
+	added   []types.DHCPStaticLease
+	deleted []types.DHCPStaticLease
+	err     error
+}
+
+func (f *fakeDHCPClient) AddDHCPStaticLease(_ context.Context, l types.DHCPStaticLease) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.added = append(f.added, l)
+	return nil
+}
+
+func (f *fakeDHCPClient) DeleteDHCPStaticLease(_ context.Context, l types.DHCPStaticLease) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.deleted = append(f.deleted, l)
+	return nil
+}
+
+func TestSyncStaticLeasesAddUpdateDelete(t *testing.T) {
+	master := []types.DHCPStaticLease{
+		{MAC: "aa:aa", IP: "10.0.0.1", Hostname: "new"},        // to add
+		{MAC: "bb:bb", IP: "10.0.0.2", Hostname: "changed-ip"}, // to update
+	}
+	current := []types.DHCPStaticLease{
+		{MAC: "bb:bb", IP: "10.0.0.99", Hostname: "changed-ip"}, // stale, differs from master
+		{MAC: "cc:cc", IP: "10.0.0.3", Hostname: "stale"},       // to delete
+	}
+
+	fake := &fakeDHCPClient{}
+	if err := syncStaticLeases(context.Background(), fake, master, current); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantAdded := map[string]bool{"aa:aa": true, "bb:bb": true}
+	if len(fake.added) != len(wantAdded) {
+		t.Fatalf("expected %d adds, got %d: %v", len(wantAdded), len(fake.added), fake.added)
+	}
+	for _, l := range fake.added {
+		if !wantAdded[l.MAC] {
+			t.Errorf("unexpected lease added: %+v", l)
+		}
+	}
+
+	wantDeleted := map[string]bool{"bb:bb": true, "cc:cc": true}
+	if len(fake.deleted) != len(wantDeleted) {
+		t.Fatalf("expected %d deletes, got %d: %v", len(wantDeleted), len(fake.deleted), fake.deleted)
+	}
+	for _, l := range fake.deleted {
+		if !wantDeleted[l.MAC] {
+			t.Errorf("unexpected lease deleted: %+v", l)
+		}
+	}
+}
+
+func TestSyncStaticLeasesNoChange(t *testing.T) {
+	leases := []types.DHCPStaticLease{{MAC: "aa:aa", IP: "10.0.0.1", Hostname: "stable"}}
+
+	fake := &fakeDHCPClient{}
+	if err := syncStaticLeases(context.Background(), fake, leases, leases); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(fake.added) != 0 || len(fake.deleted) != 0 {
+		t.Fatalf("expected no changes, got added=%v deleted=%v", fake.added, fake.deleted)
+	}
+}
+
+func TestSyncStaticLeasesPropagatesError(t *testing.T) {
+	master := []types.DHCPStaticLease{{MAC: "aa:aa", IP: "10.0.0.1"}}
+	fake := &fakeDHCPClient{err: errors.New("boom")}
+
+	if err := syncStaticLeases(context.Background(), fake, master, nil); err == nil {
+		t.Fatal("expected error to propagate")
+	}
+}
@@ -0,0 +1,118 @@
+// Package fanout runs replica sync concurrently and aggregates the per-host
+// results into a report.
+package fanout
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bakito/adguardhome-sync/pkg/client"
+	"github.com/bakito/adguardhome-sync/pkg/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	l = log.GetLogger("fanout")
+
+	replicaErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "adguardhome_sync_replica_errors_total",
+		Help: "Total number of errors syncing a resource to a replica.",
+	}, []string{"host", "resource"})
+
+	syncDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "adguardhome_sync_duration_seconds",
+		Help: "Duration of a replica sync in seconds.",
+	}, []string{"host"})
+)
+
+// SyncFunc replicates master state to a single replica, returning any errors
+// keyed by resource name (e.g. "rewrites", "filters").
+type SyncFunc func(ctx context.Context, replica client.Client) map[string]error
+
+// HostResult is the outcome of syncing a single replica.
+type HostResult struct {
+	Host     string              `json:"host"`
+	Success  bool                `json:"success"`
+	Errors   map[string][]string `json:"errors,omitempty"`
+	Duration time.Duration       `json:"duration"`
+}
+
+// SyncReport aggregates the per-host results of a fan-out sync.
+type SyncReport struct {
+	StartedAt time.Time     `json:"startedAt"`
+	Duration  time.Duration `json:"duration"`
+	Hosts     []HostResult  `json:"hosts"`
+}
+
+// Success reports whether every replica synced without error.
+func (r *SyncReport) Success() bool {
+	for _, h := range r.Hosts {
+		if !h.Success {
+			return false
+		}
+	}
+	return true
+}
+
+// Runner syncs a set of replicas concurrently using a bounded worker pool.
+type Runner struct {
+	// Workers is the number of replicas synced concurrently. Values <= 0
+	// default to 1.
+	Workers int
+}
+
+// Run syncs replicas using syncFn, collecting the per-host results into a
+// SyncReport.
+func (r *Runner) Run(ctx context.Context, replicas []client.Client, syncFn SyncFunc) *SyncReport {
+	workers := r.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+
+	report := &SyncReport{
+		StartedAt: time.Now(),
+		Hosts:     make([]HostResult, len(replicas)),
+	}
+
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, replica := range replicas {
+		i, replica := i, replica
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			report.Hosts[i] = r.syncOne(ctx, replica, syncFn)
+		}()
+	}
+	wg.Wait()
+
+	report.Duration = time.Since(report.StartedAt)
+	return report
+}
+
+func (r *Runner) syncOne(ctx context.Context, replica client.Client, syncFn SyncFunc) HostResult {
+	start := time.Now()
+	result := HostResult{Host: replica.Host(), Success: true}
+
+	errs := syncFn(ctx, replica)
+	for resource, err := range errs {
+		if err == nil {
+			continue
+		}
+		result.Success = false
+		if result.Errors == nil {
+			result.Errors = map[string][]string{}
+		}
+		result.Errors[resource] = append(result.Errors[resource], err.Error())
+		replicaErrorsTotal.WithLabelValues(result.Host, resource).Inc()
+		l.With("host", result.Host, "resource", resource, "error", err).Error("Error syncing replica")
+	}
+
+	result.Duration = time.Since(start)
+	syncDuration.WithLabelValues(result.Host).Observe(result.Duration.Seconds())
+	return result
+}
@@ -0,0 +1,75 @@
+package fanout
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bakito/adguardhome-sync/pkg/client"
+)
+
+// fakeClient satisfies client.Client for tests that only care about Host().
+type fakeClient struct {
+	client.Client
+	host string
+}
+
+func (f *fakeClient) Host() string { return f.host }
+
+func TestRunnerRunAggregatesPerHostErrors(t *testing.T) {
+	replicas := []client.Client{
+		&fakeClient{host: "ok-1"},
+		&fakeClient{host: "failing"},
+		&fakeClient{host: "ok-2"},
+	}
+
+	runner := &Runner{Workers: 2}
+	report := runner.Run(context.Background(), replicas, func(_ context.Context, replica client.Client) map[string]error {
+		if replica.Host() == "failing" {
+			return map[string]error{"filters": errors.New("boom")}
+		}
+		return nil
+	})
+
+	if len(report.Hosts) != len(replicas) {
+		t.Fatalf("expected %d host results, got %d", len(replicas), len(report.Hosts))
+	}
+	if report.Success() {
+		t.Fatal("expected overall report to be unsuccessful")
+	}
+
+	byHost := map[string]HostResult{}
+	for _, h := range report.Hosts {
+		byHost[h.Host] = h
+	}
+
+	for _, host := range []string{"ok-1", "ok-2"} {
+		if !byHost[host].Success {
+			t.Errorf("expected %s to succeed", host)
+		}
+	}
+
+	failing := byHost["failing"]
+	if failing.Success {
+		t.Fatal("expected failing host to be unsuccessful")
+	}
+	if len(failing.Errors["filters"]) != 1 {
+		t.Fatalf("expected one filters error on failing host, got %v", failing.Errors)
+	}
+}
+
+func TestRunnerRunDefaultsWorkersToOne(t *testing.T) {
+	replicas := []client.Client{&fakeClient{host: "a"}, &fakeClient{host: "b"}}
+
+	runner := &Runner{}
+	report := runner.Run(context.Background(), replicas, func(_ context.Context, _ client.Client) map[string]error {
+		return nil
+	})
+
+	if !report.Success() {
+		t.Fatal("expected report to be successful")
+	}
+	if len(report.Hosts) != len(replicas) {
+		t.Fatalf("expected %d host results, got %d", len(replicas), len(report.Hosts))
+	}
+}
@@ -0,0 +1,42 @@
+package fanout
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// ReportStore holds the most recent SyncReport and serves it over HTTP.
+type ReportStore struct {
+	mu   sync.RWMutex
+	last *SyncReport
+}
+
+// Set records report as the most recent SyncReport.
+func (s *ReportStore) Set(report *SyncReport) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.last = report
+}
+
+// Last returns the most recently recorded SyncReport, or nil if none yet.
+func (s *ReportStore) Last() *SyncReport {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.last
+}
+
+// ServeHTTP writes the last SyncReport as JSON, or 204 No Content if no sync
+// has run yet.
+func (s *ReportStore) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	report := s.Last()
+	if report == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		l.With("error", err).Error("Error encoding sync report")
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
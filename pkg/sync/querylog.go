@@ -0,0 +1,25 @@
+package sync
+
+import (
+	"context"
+
+	"github.com/bakito/adguardhome-sync/pkg/client"
+)
+
+// QueryLog converges the replica's query log configuration with the master's.
+func QueryLog(ctx context.Context, master, replica client.Client) error {
+	masterCfg, err := master.QueryLogConfig(ctx)
+	if err != nil {
+		return err
+	}
+	replicaCfg, err := replica.QueryLogConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	if *masterCfg == *replicaCfg {
+		return nil
+	}
+
+	return replica.SetQueryLogConfig(ctx, masterCfg.Enabled, masterCfg.Interval, masterCfg.AnonymizeClientIP)
+}
@@ -0,0 +1,80 @@
+package sync
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/bakito/adguardhome-sync/pkg/sync/fanout"
+)
+
+// TriggerRequest is the JSON body accepted by the sync webhook. An empty
+// Resources list syncs everything.
+type TriggerRequest struct {
+	Resources []string `json:"resources,omitempty"`
+}
+
+// TriggerFunc runs a sync of the given resources (or everything, if empty).
+type TriggerFunc func(resources []string) *fanout.SyncReport
+
+// Server exposes an HTTP API to trigger an immediate sync and read back the
+// last sync report.
+type Server struct {
+	apiKey  string
+	trigger TriggerFunc
+	reports *fanout.ReportStore
+}
+
+// NewServer creates a Server that authenticates requests with apiKey (when
+// non-empty).
+func NewServer(apiKey string, trigger TriggerFunc, reports *fanout.ReportStore) *Server {
+	return &Server{apiKey: apiKey, trigger: trigger, reports: reports}
+}
+
+// Handler returns the http.Handler serving the sync webhook API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/sync", s.authenticated(s.handleSync))
+	mux.HandleFunc("/api/v1/status", s.authenticated(s.reports.ServeHTTP))
+	return mux
+}
+
+func (s *Server) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.apiKey != "" && !validAPIKey(r, s.apiKey) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func validAPIKey(r *http.Request, apiKey string) bool {
+	provided := r.Header.Get("Authorization")
+	return subtle.ConstantTimeCompare([]byte(provided), []byte("Bearer "+apiKey)) == 1
+}
+
+func (s *Server) handleSync(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	req := &TriggerRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil && !errors.Is(err, io.EOF) {
+		l.With("error", err).Error("Error decoding sync trigger request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	report := s.trigger(req.Resources)
+	s.reports.Set(report)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		l.With("error", err).Error("Error encoding sync report")
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,118 @@
+package sync
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bakito/adguardhome-sync/pkg/sync/fanout"
+)
+
+func TestServerAuthGate(t *testing.T) {
+	triggered := false
+	srv := NewServer("s3cr3t", func(resources []string) *fanout.SyncReport {
+		triggered = true
+		return &fanout.SyncReport{}
+	}, &fanout.ReportStore{})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sync", nil)
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without credentials, got %d", rec.Code)
+	}
+	if triggered {
+		t.Fatal("trigger must not run for an unauthenticated request")
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/sync", nil)
+	req.Header.Set("Authorization", "Bearer s3cr3t")
+	rec = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 with valid credentials, got %d: %s", rec.Code, rec.Body)
+	}
+	if !triggered {
+		t.Fatal("expected trigger to run for an authenticated request")
+	}
+}
+
+func TestServerSyncResourceSelection(t *testing.T) {
+	var gotResources []string
+	srv := NewServer("", func(resources []string) *fanout.SyncReport {
+		gotResources = resources
+		return &fanout.SyncReport{}
+	}, &fanout.ReportStore{})
+
+	body, err := json.Marshal(TriggerRequest{Resources: []string{"clients", "filters"}})
+	if err != nil {
+		t.Fatalf("unexpected error marshaling request: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sync", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if len(gotResources) != 2 || gotResources[0] != "clients" || gotResources[1] != "filters" {
+		t.Fatalf("expected selected resources to reach the trigger, got %v", gotResources)
+	}
+}
+
+func TestServerSyncEmptyChunkedBody(t *testing.T) {
+	triggered := false
+	srv := NewServer("", func(resources []string) *fanout.SyncReport {
+		triggered = true
+		if resources != nil {
+			t.Fatalf("expected nil resources for an empty body, got %v", resources)
+		}
+		return &fanout.SyncReport{}
+	}, &fanout.ReportStore{})
+
+	// simulate a chunked request with no body: ContentLength == -1, not 0.
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sync", bytes.NewReader(nil))
+	req.ContentLength = -1
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 for an empty chunked body, got %d: %s", rec.Code, rec.Body)
+	}
+	if !triggered {
+		t.Fatal("expected trigger to run for a valid empty sync-everything request")
+	}
+}
+
+func TestServerStatusReflectsReportStore(t *testing.T) {
+	reports := &fanout.ReportStore{}
+	srv := NewServer("", func([]string) *fanout.SyncReport { return nil }, reports)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/status", nil)
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 before any sync has run, got %d", rec.Code)
+	}
+
+	reports.Set(&fanout.SyncReport{Hosts: []fanout.HostResult{{Host: "replica-1", Success: true}}})
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/status", nil)
+	rec = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 after a sync ran, got %d", rec.Code)
+	}
+
+	var got fanout.SyncReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("unexpected error decoding status response: %v", err)
+	}
+	if len(got.Hosts) != 1 || got.Hosts[0].Host != "replica-1" {
+		t.Fatalf("expected the last report to be returned, got %+v", got)
+	}
+}
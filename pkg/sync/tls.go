@@ -0,0 +1,37 @@
+package sync
+
+import (
+	"context"
+
+	"github.com/bakito/adguardhome-sync/pkg/client"
+	"github.com/bakito/adguardhome-sync/pkg/types"
+)
+
+// TLS converges the replica's TLS/DoH/DoT/DDR configuration with the master's.
+func TLS(ctx context.Context, master, replica client.Client) error {
+	masterCfg, err := master.TLSConfig(ctx)
+	if err != nil {
+		return err
+	}
+	replicaCfg, err := replica.TLSConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	if configurable(*masterCfg) == configurable(*replicaCfg) {
+		return nil
+	}
+
+	return replica.SetTLSConfig(ctx, *masterCfg)
+}
+
+// configurable strips the read-only validation fields /tls/status reports
+// (ValidCert, WarningValidation, ...) so they don't register as a diff.
+func configurable(cfg types.TLSConfig) types.TLSConfig {
+	cfg.ValidCert = false
+	cfg.ValidKey = false
+	cfg.ValidPair = false
+	cfg.ValidChain = false
+	cfg.WarningValidation = ""
+	return cfg
+}
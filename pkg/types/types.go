@@ -0,0 +1,126 @@
+package types
+
+import "time"
+
+// AdGuardInstance holds the connection and behavior configuration for a
+// single AdGuard Home instance (either the master or one of its replicas).
+type AdGuardInstance struct {
+	URL                string
+	APIPath            string
+	Username           string
+	Password           string
+	InsecureSkipVerify bool
+
+	// MaxRetries is the number of retries on 5xx responses and connection
+	// errors before giving up. A value of 0 disables retries.
+	MaxRetries int
+	// RetryWaitMin and RetryWaitMax bound the exponential backoff (with
+	// jitter) applied between retries.
+	RetryWaitMin time.Duration
+	RetryWaitMax time.Duration
+	// RequestsPerSecond caps the rate of outgoing requests to this instance
+	// using a token-bucket limiter. A value of 0 disables rate limiting.
+	RequestsPerSecond float64
+
+	// DHCPEnabled controls whether DHCP server configuration and static
+	// leases are synced to this instance. Set to false for replicas that
+	// intentionally run DHCP independently of the master.
+	DHCPEnabled bool
+}
+
+// QueryLog represents the response of the /querylog endpoint.
+type QueryLog struct {
+	Data   []QueryLogEntry `json:"data"`
+	Oldest string          `json:"oldest"`
+}
+
+// QueryLogEntry represents a single DNS query log entry.
+type QueryLogEntry struct {
+	Answer   string       `json:"answer,omitempty"`
+	Client   string       `json:"client"`
+	Elapsed  string       `json:"elapsedMs,omitempty"`
+	Question string       `json:"question,omitempty"`
+	Reason   string       `json:"reason,omitempty"`
+	Time     string       `json:"time"`
+	Rule     string       `json:"rule,omitempty"`
+	FilterID int64        `json:"filterId,omitempty"`
+	Rules    []ResultRule `json:"rules,omitempty"`
+}
+
+// ResultRule represents a single filter rule that matched a query, as
+// returned by AdGuard Home versions that support multiple rules per result.
+type ResultRule struct {
+	FilterListID int64  `json:"filter_list_id"`
+	Text         string `json:"text"`
+}
+
+// QueryLogConfig represents the body of the /querylog_config endpoint.
+type QueryLogConfig struct {
+	Enabled           bool   `json:"enabled"`
+	Interval          uint64 `json:"interval"`
+	AnonymizeClientIP bool   `json:"anonymize_client_ip"`
+}
+
+// TLSConfig represents the response of /tls/status and the body accepted by /tls/configure and /tls/validate.
+type TLSConfig struct {
+	Enabled          bool   `json:"enabled"`
+	ServerName       string `json:"server_name"`
+	ForceHTTPS       bool   `json:"force_https"`
+	PortHTTPS        int    `json:"port_https"`
+	PortDNSOverTLS   int    `json:"port_dns_over_tls"`
+	PortDNSOverQUIC  int    `json:"port_dns_over_quic"`
+	ServePlainHTTP2  bool   `json:"serve_plain_http2"`
+	CertificateChain string `json:"certificate_chain"`
+	PrivateKey       string `json:"private_key"`
+	CertificatePath  string `json:"certificate_path"`
+	PrivateKeyPath   string `json:"private_key_path"`
+
+	// ValidCert/ValidKey/ValidPair/ValidChain and WarningValidation are only
+	// populated by /tls/validate and /tls/status; they are ignored by
+	// /tls/configure.
+	ValidCert         bool   `json:"valid_cert,omitempty"`
+	ValidKey          bool   `json:"valid_key,omitempty"`
+	ValidPair         bool   `json:"valid_pair,omitempty"`
+	ValidChain        bool   `json:"valid_chain,omitempty"`
+	WarningValidation string `json:"warning_validation,omitempty"`
+}
+
+// DHCPStatus represents the response of the /dhcp/status endpoint.
+type DHCPStatus struct {
+	Enabled      bool              `json:"enabled"`
+	Interface    string            `json:"interface_name"`
+	V4           DHCPConfigV4      `json:"v4"`
+	V6           DHCPConfigV6      `json:"v6"`
+	StaticLeases []DHCPStaticLease `json:"static_leases"`
+	Leases       []DHCPStaticLease `json:"leases,omitempty"`
+}
+
+// DHCPConfig represents the body accepted by the /dhcp/set_config endpoint.
+type DHCPConfig struct {
+	Enabled   bool         `json:"enabled"`
+	Interface string       `json:"interface_name"`
+	V4        DHCPConfigV4 `json:"v4"`
+	V6        DHCPConfigV6 `json:"v6"`
+}
+
+// DHCPConfigV4 holds the IPv4 DHCP server settings.
+type DHCPConfigV4 struct {
+	GatewayIP     string `json:"gateway_ip"`
+	SubnetMask    string `json:"subnet_mask"`
+	RangeStart    string `json:"range_start"`
+	RangeEnd      string `json:"range_end"`
+	LeaseDuration uint32 `json:"lease_duration"`
+}
+
+// DHCPConfigV6 holds the IPv6 DHCP server settings.
+type DHCPConfigV6 struct {
+	RangeStart    string `json:"range_start"`
+	LeaseDuration uint32 `json:"lease_duration"`
+}
+
+// DHCPStaticLease represents a single static DHCP lease.
+type DHCPStaticLease struct {
+	MAC      string `json:"mac"`
+	IP       string `json:"ip"`
+	Hostname string `json:"hostname"`
+}
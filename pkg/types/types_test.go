@@ -0,0 +1,49 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestQueryLogEntryDecodesLegacyShape(t *testing.T) {
+	raw := `{"answer":"1.2.3.4","client":"10.0.0.5","time":"2026-01-01T00:00:00Z","rule":"||example.com^","filterId":42}`
+
+	var e QueryLogEntry
+	if err := json.Unmarshal([]byte(raw), &e); err != nil {
+		t.Fatalf("unexpected error decoding legacy entry: %v", err)
+	}
+
+	if e.Rule != "||example.com^" || e.FilterID != 42 {
+		t.Fatalf("expected legacy rule/filterId to decode, got %+v", e)
+	}
+	if len(e.Rules) != 0 {
+		t.Fatalf("expected no Rules on a legacy entry, got %+v", e.Rules)
+	}
+}
+
+func TestQueryLogEntryDecodesMultiRuleShape(t *testing.T) {
+	raw := `{
+		"answer":"1.2.3.4",
+		"client":"10.0.0.5",
+		"time":"2026-01-01T00:00:00Z",
+		"rules":[{"filter_list_id":1,"text":"||example.com^"},{"filter_list_id":2,"text":"||example.org^"}]
+	}`
+
+	var e QueryLogEntry
+	if err := json.Unmarshal([]byte(raw), &e); err != nil {
+		t.Fatalf("unexpected error decoding multi-rule entry: %v", err)
+	}
+
+	if e.Rule != "" || e.FilterID != 0 {
+		t.Fatalf("expected no legacy rule/filterId on a multi-rule entry, got %+v", e)
+	}
+	if len(e.Rules) != 2 {
+		t.Fatalf("expected 2 rules, got %+v", e.Rules)
+	}
+	if e.Rules[0].FilterListID != 1 || e.Rules[0].Text != "||example.com^" {
+		t.Fatalf("unexpected first rule: %+v", e.Rules[0])
+	}
+	if e.Rules[1].FilterListID != 2 || e.Rules[1].Text != "||example.org^" {
+		t.Fatalf("unexpected second rule: %+v", e.Rules[1])
+	}
+}